@@ -1,12 +1,152 @@
 package *_test
 
 import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 )
 
+// diffThreshold is the %#v length, in characters, beyond which equals
+// switches from a single-line comparison to a unified diff of the
+// pretty-printed values.
+const diffThreshold = 80
+
+// diffIfLarge returns a unified diff of the pretty-printed forms of exp and
+// act when, after dereferencing any pointers or interfaces, either side is a
+// struct, map, slice or array whose %#v representation exceeds diffThreshold
+// characters. It returns an empty string otherwise, in which case the
+// caller should fall back to a plain one-line message.
+func diffIfLarge(exp, act interface{}) string {
+	large := func(v interface{}) bool {
+		return isDiffableKind(indirect(reflect.ValueOf(v))) && len(fmt.Sprintf("%#v", v)) > diffThreshold
+	}
+	if !large(exp) && !large(act) {
+		return ""
+	}
+
+	return diffLines(prettyPrint(exp), prettyPrint(act))
+}
+
+// isDiffableKind reports whether v is a struct, map, slice or array, the
+// kinds diffIfLarge will pretty-print rather than compare as a single line.
+func isDiffableKind(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// indirect dereferences pointers and interfaces, stopping at the first nil
+// or non-pointer, non-interface value.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// prettyPrint renders v as a deterministic, multi-line representation
+// suitable for diffing: struct fields, slice elements and map entries are
+// each placed on their own line with stable indentation, and map keys are
+// sorted so that repeated calls produce identical output.
+func prettyPrint(v interface{}) string {
+	var b strings.Builder
+	prettyPrintValue(&b, reflect.ValueOf(v), 0)
+	return b.String()
+}
+
+func prettyPrintValue(b *strings.Builder, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		prettyPrintValue(b, v.Elem(), depth)
+	case reflect.Struct:
+		fmt.Fprintf(b, "%s{\n", v.Type().Name())
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			fmt.Fprintf(b, "%s  %s: ", indent, t.Field(i).Name)
+			prettyPrintValue(b, v.Field(i), depth+1)
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(b, "%s}", indent)
+	case reflect.Map:
+		b.WriteString("map{\n")
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return leafString(keys[i]) < leafString(keys[j])
+		})
+		for _, k := range keys {
+			fmt.Fprintf(b, "%s  %s: ", indent, leafString(k))
+			prettyPrintValue(b, v.MapIndex(k), depth+1)
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(b, "%s}", indent)
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[\n")
+		for i := 0; i < v.Len(); i++ {
+			fmt.Fprintf(b, "%s  ", indent)
+			prettyPrintValue(b, v.Index(i), depth+1)
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(b, "%s]", indent)
+	default:
+		b.WriteString(leafString(v))
+	}
+}
+
+// leafString renders a scalar reflect.Value in %#v style without calling
+// Interface(), which panics for values reached by recursing into unexported
+// struct fields. Kind-specific accessors such as Int()/String() remain safe
+// to call on such values, mirroring how the fmt package itself prints them.
+func leafString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return fmt.Sprintf("%#v", v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%#v", v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprintf("%#v", v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%#v", v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("%#v", v.Complex())
+	case reflect.String:
+		return fmt.Sprintf("%#v", v.String())
+	case reflect.Invalid:
+		return "nil"
+	default:
+		if v.CanInterface() {
+			return fmt.Sprintf("%#v", v.Interface())
+		}
+		return fmt.Sprintf("%#v", fmt.Sprintf("%v", v))
+	}
+}
+
+// update controls whether goldenEquals and goldenEqualsString write their
+// actual value to the golden file instead of comparing against it. It is
+// exposed as the `-update` flag so fixtures can be regenerated by running
+// `go test -update`.
+var update bool
+
+func init() {
+	flag.BoolVar(&update, "update", false, "update golden test fixtures")
+}
+
 // assert checks the provided condition and fails the test if it is false.
 // assert formats its arguments using default formatting, analogous to Println,
 // and records the text in the error log if the condition is false. The return
@@ -69,12 +209,18 @@ func okNowf(tb testing.TB, err error, format string, a ...interface{}) {
 }
 
 // equals checks the provided values for deep equality, fails the test if
-// they are not equal and records a message in the log. The return value is true
-// if the values are equal.
+// they are not equal and records a message in the log. For structs, maps,
+// slices and arrays whose %#v representation exceeds diffThreshold characters, the
+// message is a unified diff of their pretty-printed forms rather than a
+// single raw comparison. The return value is true if the values are equal.
 func equals(tb testing.TB, exp, act interface{}) bool {
 	if !reflect.DeepEqual(exp, act) {
 		tb.Helper()
-		tb.Errorf("expected %#v, got %#v", exp, act)
+		if diff := diffIfLarge(exp, act); diff != "" {
+			tb.Errorf("values are not equal:\n%s", diff)
+		} else {
+			tb.Errorf("expected %#v, got %#v", exp, act)
+		}
 		return false
 	}
 	return true
@@ -92,6 +238,256 @@ func notEquals(tb testing.TB, unexp, act interface{}) bool {
 	return true
 }
 
+// orderableKind reports whether kind represents a value that can be compared
+// using <, <=, > and >=.
+func orderableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// compare compares exp and act and returns a negative number if exp < act,
+// zero if exp == act and a positive number if exp > act. The second return
+// value is false if exp and act could not be compared, either because their
+// kinds differ or because the kind is not orderable.
+func compare(exp, act interface{}) (int, bool) {
+	ve, va := reflect.ValueOf(exp), reflect.ValueOf(act)
+	if ve.Kind() != va.Kind() || !orderableKind(ve.Kind()) {
+		return 0, false
+	}
+
+	switch ve.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e, a := ve.Int(), va.Int()
+		switch {
+		case e < a:
+			return -1, true
+		case e > a:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e, a := ve.Uint(), va.Uint()
+		switch {
+		case e < a:
+			return -1, true
+		case e > a:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case reflect.Float32, reflect.Float64:
+		e, a := ve.Float(), va.Float()
+		switch {
+		case e < a:
+			return -1, true
+		case e > a:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case reflect.String:
+		e, a := ve.String(), va.String()
+		switch {
+		case e < a:
+			return -1, true
+		case e > a:
+			return 1, true
+		default:
+			return 0, true
+		}
+	default:
+		return 0, false
+	}
+}
+
+// less checks that exp is less than act, fails the test if it is not and
+// records a message in the log. The return value is true if exp is less than
+// act.
+func less(tb testing.TB, exp, act interface{}) bool {
+	tb.Helper()
+	c, ok := compare(exp, act)
+	if !ok {
+		tb.Errorf("cannot compare %#v and %#v", exp, act)
+		return false
+	}
+	if c >= 0 {
+		tb.Errorf("expected %#v < %#v", exp, act)
+		return false
+	}
+	return true
+}
+
+// lessOrEqual checks that exp is less than or equal to act, fails the test
+// if it is not and records a message in the log. The return value is true if
+// exp is less than or equal to act.
+func lessOrEqual(tb testing.TB, exp, act interface{}) bool {
+	tb.Helper()
+	c, ok := compare(exp, act)
+	if !ok {
+		tb.Errorf("cannot compare %#v and %#v", exp, act)
+		return false
+	}
+	if c > 0 {
+		tb.Errorf("expected %#v <= %#v", exp, act)
+		return false
+	}
+	return true
+}
+
+// greater checks that exp is greater than act, fails the test if it is not
+// and records a message in the log. The return value is true if exp is
+// greater than act.
+func greater(tb testing.TB, exp, act interface{}) bool {
+	tb.Helper()
+	c, ok := compare(exp, act)
+	if !ok {
+		tb.Errorf("cannot compare %#v and %#v", exp, act)
+		return false
+	}
+	if c <= 0 {
+		tb.Errorf("expected %#v > %#v", exp, act)
+		return false
+	}
+	return true
+}
+
+// greaterOrEqual checks that exp is greater than or equal to act, fails the
+// test if it is not and records a message in the log. The return value is
+// true if exp is greater than or equal to act.
+func greaterOrEqual(tb testing.TB, exp, act interface{}) bool {
+	tb.Helper()
+	c, ok := compare(exp, act)
+	if !ok {
+		tb.Errorf("cannot compare %#v and %#v", exp, act)
+		return false
+	}
+	if c < 0 {
+		tb.Errorf("expected %#v >= %#v", exp, act)
+		return false
+	}
+	return true
+}
+
+// goldenEquals checks that actual matches the contents of the golden file at
+// path, fails the test if it does not and records a diff of the two in the
+// log. When the -update flag is set, goldenEquals instead writes actual to
+// path, creating any missing parent directories, so fixtures can be
+// regenerated with `go test -update`. The return value is true if actual
+// matches the golden file, or if it was written because -update was set.
+func goldenEquals(tb testing.TB, actual []byte, path string) bool {
+	tb.Helper()
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Errorf("failed to create golden file directory for %s: %v", path, err)
+			return false
+		}
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			tb.Errorf("failed to update golden file %s: %v", path, err)
+			return false
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		tb.Errorf("failed to read golden file %s: %v", path, err)
+		return false
+	}
+
+	if string(expected) == string(actual) {
+		return true
+	}
+
+	tb.Errorf("actual does not match golden file %s:\n%s", path, diffLines(string(expected), string(actual)))
+	return false
+}
+
+// goldenEqualsString is the string-valued counterpart to goldenEquals.
+func goldenEqualsString(tb testing.TB, actual string, path string) bool {
+	tb.Helper()
+	return goldenEquals(tb, []byte(actual), path)
+}
+
+// diffLines returns a unified diff between the lines of expected and actual,
+// based on their longest common subsequence: lines present in both are
+// prefixed with a space, removed lines with "-" and added lines with "+".
+func diffLines(expected, actual string) string {
+	expLines := strings.Split(expected, "\n")
+	actLines := strings.Split(actual, "\n")
+	lcs := longestCommonSubsequence(expLines, actLines)
+
+	var b strings.Builder
+	i, j, k := 0, 0, 0
+	for k < len(lcs) {
+		for expLines[i] != lcs[k] {
+			fmt.Fprintf(&b, "-%s\n", expLines[i])
+			i++
+		}
+		for actLines[j] != lcs[k] {
+			fmt.Fprintf(&b, "+%s\n", actLines[j])
+			j++
+		}
+		fmt.Fprintf(&b, " %s\n", lcs[k])
+		i++
+		j++
+		k++
+	}
+	for ; i < len(expLines); i++ {
+		fmt.Fprintf(&b, "-%s\n", expLines[i])
+	}
+	for ; j < len(actLines); j++ {
+		fmt.Fprintf(&b, "+%s\n", actLines[j])
+	}
+	return b.String()
+}
+
+// longestCommonSubsequence returns the longest common subsequence of a and
+// b, computed with the dynamic-programming table that underlies the
+// Myers/Hunt–McIlroy family of diff algorithms.
+func longestCommonSubsequence(a, b []string) []string {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				dp[i][j] = dp[i+1][j+1] + 1
+			case dp[i+1][j] >= dp[i][j+1]:
+				dp[i][j] = dp[i+1][j]
+			default:
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+
+	var lcs []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lcs = append(lcs, a[i])
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return lcs
+}
+
 // call contains information about a called function.
 type call struct {
 	// name contains the name of the function.
@@ -120,6 +516,52 @@ func (c *callRecorder) record(args ...interface{}) {
 	}
 }
 
+// anyType is the type of the anyArg sentinel.
+type anyType struct{}
+
+// anyArg is a sentinel value that, when used in place of an expected
+// argument in createAsserter, createUnorderedAsserter or
+// createSubsetAsserter, matches any recorded value at that position.
+var anyArg = anyType{}
+
+// argMatcher matches a single recorded argument using a predicate instead of
+// reflect.DeepEqual. Construct one with matches.
+type argMatcher struct {
+	fn func(interface{}) bool
+}
+
+// matches creates an argMatcher that, when used in place of an expected
+// argument, considers a recorded value equal if fn returns true for it. This
+// is useful for opaque values such as timestamps or generated IDs that
+// cannot be asserted on directly.
+func matches(fn func(interface{}) bool) argMatcher {
+	return argMatcher{fn: fn}
+}
+
+// argsEqual reports whether act satisfies the expectations in exp, treating
+// anyArg as a wildcard and argMatcher values as custom predicates in place
+// of reflect.DeepEqual.
+func argsEqual(exp, act []interface{}) bool {
+	if len(exp) != len(act) {
+		return false
+	}
+	for i := range exp {
+		switch e := exp[i].(type) {
+		case anyType:
+			continue
+		case argMatcher:
+			if !e.fn(act[i]) {
+				return false
+			}
+		default:
+			if !reflect.DeepEqual(exp[i], act[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // callAsserter checks that the next function has the correct name and
 // parameters, fails the test if they are not equal and records a message in
 // the log. The return value is true if the name and parameters are equal.
@@ -150,7 +592,7 @@ func (c callRecorder) createAsserter(tb testing.TB) (callAsserter, endCallAssert
 			tb.Errorf("%d: expected call %s, got call %s", i, name, call.name)
 			i++
 			return false
-		} else if !reflect.DeepEqual(args, call.args) {
+		} else if !argsEqual(args, call.args) {
 			tb.Errorf("%d: expected args %v, got args %v", i, args, call.args)
 			i++
 			return false
@@ -168,4 +610,122 @@ func (c callRecorder) createAsserter(tb testing.TB) (callAsserter, endCallAssert
 	}
 
 	return ca, ec
-}
\ No newline at end of file
+}
+
+// createUnorderedAsserter creates a callAsserter than can be used to ensure
+// that a multiset of calls was satisfied, regardless of the order in which
+// they were made. Each recorded call must be matched exactly once.
+func (c callRecorder) createUnorderedAsserter(tb testing.TB) (callAsserter, endCallAsserter) {
+	remaining := make([]call, len(c.calls))
+	copy(remaining, c.calls)
+
+	ca := func(name string, args ...interface{}) bool {
+		tb.Helper()
+		for i, rc := range remaining {
+			if rc.name == name && argsEqual(args, rc.args) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				return true
+			}
+		}
+		tb.Errorf("expected call %s%v was not recorded", name, args)
+		return false
+	}
+
+	ec := func() bool {
+		if len(remaining) > 0 {
+			tb.Helper()
+			tb.Errorf("expected no more calls, got %d unmatched: %v", len(remaining), remaining)
+			return false
+		}
+		return true
+	}
+
+	return ca, ec
+}
+
+// createSubsetAsserter creates a callAsserter than can be used to ensure
+// that every expected call was recorded at least once. Additional recorded
+// calls that were not expected do not cause a failure.
+func (c callRecorder) createSubsetAsserter(tb testing.TB) (callAsserter, endCallAsserter) {
+	ca := func(name string, args ...interface{}) bool {
+		tb.Helper()
+		for _, rc := range c.calls {
+			if rc.name == name && argsEqual(args, rc.args) {
+				return true
+			}
+		}
+		tb.Errorf("expected call %s%v was not recorded", name, args)
+		return false
+	}
+
+	ec := func() bool {
+		return true
+	}
+
+	return ca, ec
+}
+
+// bodyPreviewLen is the number of response body bytes included in HTTP
+// assertion failure messages.
+const bodyPreviewLen = 256
+
+// doHTTPRequest builds an *http.Request for method, url and body, invokes
+// handler's ServeHTTP against an httptest.ResponseRecorder and returns the
+// recorder.
+func doHTTPRequest(handler http.Handler, method, url string, body io.Reader) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, body)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// previewBody returns the first bodyPreviewLen bytes of body, followed by
+// "..." when body is longer than that, for use in failure messages.
+func previewBody(body []byte) string {
+	if len(body) > bodyPreviewLen {
+		return string(body[:bodyPreviewLen]) + "..."
+	}
+	return string(body)
+}
+
+// httpStatus checks that handler responds to a method/url/body request with
+// expectedCode, fails the test if it does not and records the method, url
+// and a preview of the response body in the log. The return value is true
+// if the status codes match.
+func httpStatus(tb testing.TB, handler http.Handler, method, url string, body io.Reader, expectedCode int) bool {
+	tb.Helper()
+	rec := doHTTPRequest(handler, method, url, body)
+	if rec.Code != expectedCode {
+		tb.Errorf("%s %s: expected status %d, got %d\nbody: %s", method, url, expectedCode, rec.Code, previewBody(rec.Body.Bytes()))
+		return false
+	}
+	return true
+}
+
+// httpBodyContains checks that handler's response body to a method/url/body
+// request contains substr, fails the test if it does not and records the
+// method, url and a preview of the response body in the log. The return
+// value is true if the body contains substr.
+func httpBodyContains(tb testing.TB, handler http.Handler, method, url string, body io.Reader, substr string) bool {
+	tb.Helper()
+	rec := doHTTPRequest(handler, method, url, body)
+	if !strings.Contains(rec.Body.String(), substr) {
+		tb.Errorf("%s %s: expected body to contain %q\nbody: %s", method, url, substr, previewBody(rec.Body.Bytes()))
+		return false
+	}
+	return true
+}
+
+// httpHeader checks that handler's response to a method/url/body request
+// sets headerName to expectedValue, fails the test if it does not and
+// records the method, url and a preview of the response body in the log.
+// The return value is true if the header values match.
+func httpHeader(tb testing.TB, handler http.Handler, method, url string, body io.Reader, headerName, expectedValue string) bool {
+	tb.Helper()
+	rec := doHTTPRequest(handler, method, url, body)
+	if got := rec.Header().Get(headerName); got != expectedValue {
+		tb.Errorf("%s %s: expected header %s to be %q, got %q\nbody: %s", method, url, headerName, expectedValue, got, previewBody(rec.Body.Bytes()))
+		return false
+	}
+	return true
+}